@@ -0,0 +1,22 @@
+package analyzer
+
+import "github.com/safedep/vet/pkg/models"
+
+// Analyzer inspects a single scanned package, typically to flag it through
+// a configured reporter when it matches some condition (a CEL filter, a
+// suppression, an exception).
+type Analyzer interface {
+	Name() string
+	Analyze(pkg *models.Package) error
+}
+
+// Finisher is implemented by analyzers that need to flush accumulated state
+// once every package has been analyzed (e.g. writing out a generated
+// exceptions file). It is a separate, optional interface rather than part
+// of Analyzer so that adding a new analyzer which needs Finish doesn't
+// force every existing Analyzer implementation to grow a matching no-op
+// method. Callers should probe for it with a type assertion after the scan
+// completes - see query.go.
+type Finisher interface {
+	Finish() error
+}
@@ -0,0 +1,265 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/safedep/vet/pkg/analyzer/filter"
+	"github.com/safedep/vet/pkg/models"
+	"github.com/safedep/vet/pkg/reporter"
+	"gopkg.in/yaml.v3"
+)
+
+// FilterSeverity classifies how serious a filter-suite rule match is.
+type FilterSeverity string
+
+const (
+	FilterSeverityError   FilterSeverity = "error"
+	FilterSeverityWarning FilterSeverity = "warning"
+	FilterSeverityInfo    FilterSeverity = "info"
+)
+
+// FilterAction determines what a matched rule does to the query command's
+// exit status, independent of its severity label.
+type FilterAction string
+
+const (
+	FilterActionBlock  FilterAction = "block"
+	FilterActionWarn   FilterAction = "warn"
+	FilterActionExempt FilterAction = "exempt"
+)
+
+// filterSuiteRule is a single named CEL expression in a filter suite, along
+// with how it should gate the build when it matches.
+type filterSuiteRule struct {
+	Name     string         `yaml:"name"`
+	Value    string         `yaml:"value"`
+	Summary  string         `yaml:"summary,omitempty"`
+	Severity FilterSeverity `yaml:"severity,omitempty"`
+	Action   FilterAction   `yaml:"action,omitempty"`
+}
+
+// effectiveAction returns the rule's Action if set, otherwise one derived
+// from Severity so existing filter suites (authored before this field
+// existed) keep failing the build the way `--filter-fail` always did.
+func (r *filterSuiteRule) effectiveAction() FilterAction {
+	if r.Action != "" {
+		return r.Action
+	}
+
+	switch r.Severity {
+	case FilterSeverityWarning, FilterSeverityInfo:
+		return FilterActionWarn
+	default:
+		return FilterActionBlock
+	}
+}
+
+// filterSuite is the top-level CEL Filter Suite document.
+type filterSuite struct {
+	Filters []filterSuiteRule `yaml:"filters"`
+}
+
+func loadFilterSuite(path string) (*filterSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read filter suite %s: %w", path, err)
+	}
+
+	var suite filterSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse filter suite %s: %w", path, err)
+	}
+
+	return &suite, nil
+}
+
+// PolicyMatch is a single rule match recorded for the machine-readable
+// policy report.
+type PolicyMatch struct {
+	Package    string         `json:"package"`
+	Ecosystem  string         `json:"ecosystem"`
+	Rule       string         `json:"rule"`
+	Severity   FilterSeverity `json:"severity"`
+	Action     FilterAction   `json:"action"`
+	Expression string         `json:"expression"`
+}
+
+// PolicyReport is the machine-readable summary of a `vet query
+// --filter-suite` run, meant to be consumed as a security gate in CI
+// instead of the previous all-or-nothing `--filter-fail` boolean.
+type PolicyReport struct {
+	Blocked []PolicyMatch `json:"blocked"`
+	Warned  []PolicyMatch `json:"warned"`
+}
+
+// WriteJSON writes the policy report as JSON to path.
+func (r *PolicyReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// PrintWarnedSummary writes a structured, human-readable summary of every
+// `warn` rule match to stdout. `warn` matches never fail the build and, with
+// no --policy-report path configured, would otherwise leave no trace at all
+// that a rule matched.
+func (r *PolicyReport) PrintWarnedSummary() {
+	if len(r.Warned) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%d package(s) matched a warn rule:\n", len(r.Warned))
+	for _, m := range r.Warned {
+		fmt.Printf("  - [%s] %s (%s) matched %q: %s\n", m.Severity, m.Package, m.Ecosystem, m.Rule, m.Expression)
+	}
+}
+
+// CelFilterSuiteAnalyzer evaluates every rule in a CEL Filter Suite against
+// each scanned package, recording each match's severity and action instead
+// of collapsing the whole suite into a single pass/fail boolean.
+type CelFilterSuiteAnalyzer struct {
+	evaluator   filter.Evaluator
+	rulesByName map[string]*filterSuiteRule
+	report      *PolicyReport
+	failOnBlock bool
+	sarif       sarifRecorder
+}
+
+// sarifRecorder is implemented by reporter's SARIF reporter. Declared here
+// rather than imported as a concrete type so CelFilterSuiteAnalyzer only
+// depends on the one method it needs.
+type sarifRecorder interface {
+	AddMatch(match reporter.SarifMatch)
+}
+
+// SetSarifRecorder wires a SARIF reporter into the analyzer so every
+// matched rule is also recorded as a SARIF result, in addition to the
+// policy report. A nil recorder (the default) disables SARIF recording.
+func (a *CelFilterSuiteAnalyzer) SetSarifRecorder(sarif sarifRecorder) {
+	a.sarif = sarif
+}
+
+// NewCelFilterSuiteAnalyzer creates an analyzer that evaluates a CEL Filter
+// Suite loaded from path. failOnBlock controls whether ShouldFail reports a
+// non-zero exit when a `block` rule matches; `warn` and `exempt` rules
+// never fail the build on their own, they only show up in the policy
+// report.
+func NewCelFilterSuiteAnalyzer(path string, failOnBlock bool) (*CelFilterSuiteAnalyzer, error) {
+	suite, err := loadFilterSuite(path)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator, err := filter.NewEvaluator("cel-filter-suite", true)
+	if err != nil {
+		return nil, err
+	}
+
+	rulesByName := make(map[string]*filterSuiteRule, len(suite.Filters))
+	for i := range suite.Filters {
+		rule := &suite.Filters[i]
+		if err := evaluator.AddFilter(rule.Name, rule.Value); err != nil {
+			return nil, fmt.Errorf("failed to add filter %s: %w", rule.Name, err)
+		}
+
+		rulesByName[rule.Name] = rule
+	}
+
+	return &CelFilterSuiteAnalyzer{
+		evaluator:   evaluator,
+		rulesByName: rulesByName,
+		report:      &PolicyReport{},
+		failOnBlock: failOnBlock,
+	}, nil
+}
+
+func (a *CelFilterSuiteAnalyzer) Name() string {
+	return "CEL Filter Suite Analyzer"
+}
+
+func (a *CelFilterSuiteAnalyzer) Analyze(pkg *models.Package) error {
+	result, err := a.evaluator.EvalPackage(pkg)
+	if err != nil {
+		return err
+	}
+
+	if !result.Matched() {
+		return nil
+	}
+
+	// Record every rule that matched, not just the first - a package can
+	// match both a `warn` and a `block` rule, and the whole point of this
+	// analyzer is to surface each rule's own severity/action rather than
+	// have one rule's outcome silently shadow another by suite order.
+	for _, m := range result.Matches() {
+		rule, ok := a.rulesByName[m.Name]
+		if !ok {
+			continue
+		}
+
+		match := PolicyMatch{
+			Package:    pkg.PackageDetails.Name,
+			Ecosystem:  string(pkg.PackageDetails.Ecosystem),
+			Rule:       rule.Name,
+			Severity:   rule.Severity,
+			Action:     rule.effectiveAction(),
+			Expression: m.Expression,
+		}
+
+		switch match.Action {
+		case FilterActionBlock:
+			a.report.Blocked = append(a.report.Blocked, match)
+		case FilterActionWarn:
+			a.report.Warned = append(a.report.Warned, match)
+		}
+
+		if a.sarif != nil {
+			a.sarif.AddMatch(reporter.SarifMatch{
+				RuleID: rule.Name,
+				// SARIF severity reflects the vulnerability that triggered
+				// the match, not the rule's own severity label (which
+				// classifies the policy, e.g. "warning", not the finding).
+				Expression: m.Expression,
+				Severity:   result.HighestVulnRisk(),
+				Path:       manifestPath(pkg),
+				PackageRef: fmt.Sprintf("%s@%s", pkg.PackageDetails.Name, pkg.PackageDetails.Version),
+			})
+		}
+	}
+
+	return nil
+}
+
+// manifestPath returns the manifest file a matched package was found in, or
+// an empty string if the package isn't associated with one.
+func manifestPath(pkg *models.Package) string {
+	if pkg.Manifest == nil {
+		return ""
+	}
+
+	return pkg.Manifest.Path
+}
+
+// Report returns the policy report accumulated so far. Call after the scan
+// completes for a final result.
+func (a *CelFilterSuiteAnalyzer) Report() *PolicyReport {
+	return a.report
+}
+
+// ShouldFail returns true if the query command should exit non-zero given
+// the matches recorded so far - i.e. failOnBlock is set and at least one
+// `block` rule matched.
+func (a *CelFilterSuiteAnalyzer) ShouldFail() bool {
+	return a.failOnBlock && len(a.report.Blocked) > 0
+}
+
+// Finish is a no-op - the accumulated PolicyReport is pulled explicitly via
+// Report() once the scan completes.
+func (a *CelFilterSuiteAnalyzer) Finish() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/safedep/vet/pkg/models"
+)
+
+func TestCelFilterSuiteAnalyzer_RecordsEveryMatchedRule(t *testing.T) {
+	suitePath := filepath.Join(t.TempDir(), "suite.yml")
+	suiteYAML := `filters:
+  - name: critical-cve
+    value: pkg.name == "foobar"
+    severity: error
+    action: block
+  - name: legacy-license
+    value: pkg.name == "foobar"
+    severity: warning
+    action: warn
+`
+
+	if err := os.WriteFile(suitePath, []byte(suiteYAML), 0o644); err != nil {
+		t.Fatalf("write suite: %v", err)
+	}
+
+	a, err := NewCelFilterSuiteAnalyzer(suitePath, false)
+	if err != nil {
+		t.Fatalf("NewCelFilterSuiteAnalyzer: %v", err)
+	}
+
+	pkg := &models.Package{
+		PackageDetails: models.PackageDetails{
+			Ecosystem: "npm",
+			Name:      "foobar",
+			Version:   "1.0.0",
+		},
+	}
+
+	if err := a.Analyze(pkg); err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	report := a.Report()
+
+	// Both rules matched the same package; a package matching both a warn
+	// and a block rule must not have one silently shadow the other by
+	// suite order.
+	if len(report.Blocked) != 1 {
+		t.Errorf("expected 1 blocked match, got %d", len(report.Blocked))
+	}
+
+	if len(report.Warned) != 1 {
+		t.Errorf("expected 1 warned match, got %d", len(report.Warned))
+	}
+}
+
+func TestPolicyReport_PrintWarnedSummary(t *testing.T) {
+	report := &PolicyReport{
+		Warned: []PolicyMatch{
+			{Package: "foobar", Ecosystem: "npm", Rule: "legacy-license", Severity: FilterSeverityWarning, Expression: `pkg.name == "foobar"`},
+		},
+	}
+
+	old := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	os.Stdout = w
+
+	report.PrintWarnedSummary()
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		t.Fatalf("read captured stdout: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "foobar") || !strings.Contains(out, "legacy-license") {
+		t.Errorf("expected warned summary to mention the package and rule, got %q", out)
+	}
+}
@@ -0,0 +1,248 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/safedep/dry/utils"
+	"github.com/safedep/vet/pkg/analyzer/filter"
+	"github.com/safedep/vet/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// ExceptionsGeneratorConfig configures ExceptionsGenerator.
+type ExceptionsGeneratorConfig struct {
+	// Path to read/write the exceptions YAML file.
+	Path string
+
+	// ExpiresOn is the expiry date (2006-01-02) stamped on newly generated
+	// records.
+	ExpiresOn string
+
+	// Filter restricts exception generation to packages matching this CEL
+	// expression. An empty filter generates an exception for every scanned
+	// package.
+	Filter string
+
+	// Justification is used for every newly generated record when set,
+	// skipping the interactive prompt - for non-interactive CI use.
+	Justification string
+}
+
+// ExceptionsGenerator records a reviewed, justified exception for every
+// package that matches Filter (or every package, if Filter is empty),
+// writing them to a YAML file on Finish. Re-running against the same file
+// updates existing records (matched by a stable hash of
+// ecosystem/name/version/rule) in place instead of duplicating them.
+type ExceptionsGenerator struct {
+	config    ExceptionsGeneratorConfig
+	evaluator filter.Evaluator
+	expiresAt time.Time
+
+	existing map[string]*filter.ExceptionRecord
+	records  map[string]*filter.ExceptionRecord
+}
+
+// NewExceptionsGenerator creates an analyzer that generates or updates
+// exception records in config.Path.
+func NewExceptionsGenerator(config ExceptionsGeneratorConfig) (*ExceptionsGenerator, error) {
+	expiresAt, err := time.Parse("2006-01-02", config.ExpiresOn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --exceptions-till value %q: %w", config.ExpiresOn, err)
+	}
+
+	var evaluator filter.Evaluator
+	if !utils.IsEmptyString(config.Filter) {
+		evaluator, err = filter.NewEvaluator("exceptions-generator", false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := evaluator.AddFilter("exceptions-filter", config.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := loadExistingExceptions(config.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExceptionsGenerator{
+		config:    config,
+		evaluator: evaluator,
+		expiresAt: expiresAt,
+		existing:  existing,
+		records:   map[string]*filter.ExceptionRecord{},
+	}, nil
+}
+
+func loadExistingExceptions(path string) (map[string]*filter.ExceptionRecord, error) {
+	existing := map[string]*filter.ExceptionRecord{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return existing, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read exceptions file %s: %w", path, err)
+	}
+
+	var doc filter.ExceptionsDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse exceptions file %s: %w", path, err)
+	}
+
+	for _, record := range doc.Exceptions {
+		existing[record.Hash] = record
+	}
+
+	return existing, nil
+}
+
+func (g *ExceptionsGenerator) Name() string {
+	return "Exceptions Generator"
+}
+
+func (g *ExceptionsGenerator) Analyze(pkg *models.Package) error {
+	ruleName := ""
+
+	if g.evaluator != nil {
+		result, err := g.evaluator.EvalPackage(pkg)
+		if err != nil {
+			return err
+		}
+
+		if !result.Matched() {
+			return nil
+		}
+
+		ruleName = result.MatchedFilterName()
+	}
+
+	ecosystem := string(pkg.PackageDetails.Ecosystem)
+	name := pkg.PackageDetails.Name
+	version := pkg.PackageDetails.Version
+	hash := filter.ExceptionHash(ecosystem, name, version, ruleName)
+
+	if record, ok := g.existing[hash]; ok {
+		// Already reviewed - carry the existing record (and its
+		// justification) forward instead of prompting again.
+		g.records[hash] = record
+		return nil
+	}
+
+	justification := g.config.Justification
+	if justification == "" {
+		var err error
+		justification, err = promptJustification(name, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	g.records[hash] = &filter.ExceptionRecord{
+		Hash:          hash,
+		Package:       name,
+		Version:       version,
+		Ecosystem:     ecosystem,
+		Rule:          ruleName,
+		CVEs:          matchedCVEs(pkg),
+		Licenses:      matchedLicenses(pkg),
+		Justification: justification,
+		Author:        gitUserEmail(),
+		CreatedAt:     time.Now().UTC(),
+		ExpiresAt:     g.expiresAt,
+	}
+
+	return nil
+}
+
+func matchedCVEs(pkg *models.Package) []string {
+	insight := utils.SafelyGetValue(pkg.Insights)
+
+	cves := []string{}
+	for _, vuln := range utils.SafelyGetValue(insight.Vulnerabilities) {
+		for _, alias := range utils.SafelyGetValue(vuln.Aliases) {
+			if strings.HasPrefix(strings.ToUpper(alias), "CVE-") {
+				cves = append(cves, alias)
+			}
+		}
+	}
+
+	return cves
+}
+
+func matchedLicenses(pkg *models.Package) []string {
+	insight := utils.SafelyGetValue(pkg.Insights)
+
+	licenses := []string{}
+	for _, lic := range utils.SafelyGetValue(insight.Licenses) {
+		licenses = append(licenses, string(lic))
+	}
+
+	return licenses
+}
+
+// promptJustification asks the user for a justification on an interactive
+// terminal, and errors out otherwise - exceptions must always have a
+// reason, and CI runs should pass --exceptions-justification instead.
+func promptJustification(name, version string) (string, error) {
+	stat, err := os.Stdin.Stat()
+	if err != nil || (stat.Mode()&os.ModeCharDevice) == 0 {
+		return "", fmt.Errorf("a justification is required to generate an exception for %s@%s; "+
+			"pass --exceptions-justification when running non-interactively", name, version)
+	}
+
+	fmt.Printf("Justification for exception on %s@%s: ", name, version)
+
+	reader := bufio.NewReader(os.Stdin)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read justification: %w", err)
+	}
+
+	justification := strings.TrimSpace(line)
+	if justification == "" {
+		return "", fmt.Errorf("a justification is required to generate an exception for %s@%s", name, version)
+	}
+
+	return justification, nil
+}
+
+func gitUserEmail() string {
+	out, err := exec.Command("git", "config", "user.email").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// Finish writes every generated or carried-forward exception record to
+// config.Path as YAML.
+func (g *ExceptionsGenerator) Finish() error {
+	doc := filter.ExceptionsDocument{Exceptions: make([]*filter.ExceptionRecord, 0, len(g.records))}
+	for _, record := range g.records {
+		doc.Exceptions = append(doc.Exceptions, record)
+	}
+
+	// g.records is a map, so its iteration order (and therefore the order
+	// above) is nondeterministic - sort by hash so re-running against an
+	// unchanged scan produces a byte-identical file instead of a spurious
+	// diff every time.
+	sort.Slice(doc.Exceptions, func(i, j int) bool {
+		return doc.Exceptions[i].Hash < doc.Exceptions[j].Hash
+	})
+
+	data, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(g.config.Path, data, 0644)
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/safedep/vet/pkg/analyzer/filter"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExceptionsGenerator_Finish_DeterministicOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exceptions.yml")
+
+	g := &ExceptionsGenerator{
+		config: ExceptionsGeneratorConfig{Path: path},
+		records: map[string]*filter.ExceptionRecord{
+			"ccc": {Hash: "ccc", Package: "c"},
+			"aaa": {Hash: "aaa", Package: "a"},
+			"bbb": {Hash: "bbb", Package: "b"},
+		},
+	}
+
+	if err := g.Finish(); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read exceptions file: %v", err)
+	}
+
+	var doc filter.ExceptionsDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal exceptions file: %v", err)
+	}
+
+	want := []string{"aaa", "bbb", "ccc"}
+	if len(doc.Exceptions) != len(want) {
+		t.Fatalf("got %d exceptions, want %d", len(doc.Exceptions), len(want))
+	}
+
+	for i, hash := range want {
+		if doc.Exceptions[i].Hash != hash {
+			t.Errorf("exception %d: got hash %q, want %q", i, doc.Exceptions[i].Hash, hash)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package filter
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/google/cel-go/cel"
@@ -12,6 +16,7 @@ import (
 	"github.com/safedep/vet/gen/filterinput"
 	"github.com/safedep/vet/gen/insightapi"
 	"github.com/safedep/vet/pkg/common/logger"
+	"github.com/safedep/vet/pkg/filter/stdlib"
 	"github.com/safedep/vet/pkg/models"
 )
 
@@ -34,13 +39,142 @@ var (
 type Evaluator interface {
 	AddFilter(name, filter string) error
 	EvalPackage(pkg *models.Package) (*filterEvaluationResult, error)
+
+	// EvalPackages evaluates all added filters against every package using
+	// a worker pool, which is significantly faster than calling EvalPackage
+	// in a loop for large manifests. Results are returned in the same order
+	// as pkgs. Evaluation stops early and returns an error if ctx is
+	// cancelled or, when ignoreError is false, if any package fails to
+	// evaluate.
+	EvalPackages(ctx context.Context, pkgs []*models.Package) ([]*filterEvaluationResult, error)
+
+	// SetParallelism overrides the number of workers EvalPackages uses.
+	// Defaults to runtime.GOMAXPROCS(0).
+	SetParallelism(n int)
+}
+
+type filterProgram struct {
+	name    string
+	expr    string
+	program cel.Program
+}
+
+type filterEvaluationResult struct {
+	match               bool
+	program             *filterProgram
+	matches             []*filterProgram
+	expiredSuppressions []*SuppressionEntry
+	highestVulnRisk     string
+}
+
+// FilterMatch identifies one filter program that matched a package,
+// without exposing the unexported filterProgram type across package
+// boundaries.
+type FilterMatch struct {
+	Name       string
+	Expression string
+}
+
+// Matched returns true if any filter program matched the package.
+func (r *filterEvaluationResult) Matched() bool {
+	return r.match
+}
+
+// MatchedFilterName returns the name of the first filter that matched, or
+// an empty string if none did. Callers that only care about a single
+// pass/fail boolean (e.g. `vet query --filter`) can use this; callers that
+// need every matching rule (e.g. the filter suite's per-rule severity/
+// action gating) should use Matches instead.
+func (r *filterEvaluationResult) MatchedFilterName() string {
+	if r.program == nil {
+		return ""
+	}
+
+	return r.program.name
+}
+
+// Matches returns every filter program that matched the package, in suite
+// order.
+func (r *filterEvaluationResult) Matches() []FilterMatch {
+	matches := make([]FilterMatch, 0, len(r.matches))
+	for _, prog := range r.matches {
+		matches = append(matches, FilterMatch{Name: prog.name, Expression: prog.expr})
+	}
+
+	return matches
+}
+
+// ExpiredSuppressions returns the suppression entries that were matched
+// against this package's vulnerabilities but had already lapsed.
+func (r *filterEvaluationResult) ExpiredSuppressions() []*SuppressionEntry {
+	return r.expiredSuppressions
+}
+
+// HighestVulnRisk returns the highest severity bucket ("critical", "high",
+// "medium" or "low") with at least one live (non-suppressed) vulnerability
+// on the evaluated package, or an empty string if it has none. Callers that
+// render a match at some external severity scale (e.g. SARIF's
+// error/warning/note levels) should derive it from this rather than the
+// filter suite rule's own severity label, which classifies the rule, not
+// the vulnerability that triggered it.
+func (r *filterEvaluationResult) HighestVulnRisk() string {
+	return r.highestVulnRisk
+}
+
+// MatchedFilterExpression returns the raw CEL expression of the first
+// filter that matched, or an empty string if none did.
+func (r *filterEvaluationResult) MatchedFilterExpression() string {
+	if r.program == nil {
+		return ""
+	}
+
+	return r.program.expr
 }
 
 type filterEvaluator struct {
-	name        string
-	env         *cel.Env
-	programs    []*filterProgram
-	ignoreError bool
+	name         string
+	env          *cel.Env
+	programs     []*filterProgram
+	ignoreError  bool
+	suppressions *SuppressionList
+	parallelism  int
+
+	inputCache sync.Map // map[string]*filterInputBuildResult, keyed by package identity
+}
+
+// defaultEvalParallelism is used by EvalPackages when the caller hasn't
+// overridden it with SetParallelism.
+func defaultEvalParallelism() int {
+	if n := runtime.GOMAXPROCS(0); n > 0 {
+		return n
+	}
+
+	return 1
+}
+
+// SetParallelism overrides the number of workers EvalPackages uses to
+// evaluate packages concurrently. It is a no-op for values < 1.
+func (f *filterEvaluator) SetParallelism(n int) {
+	if n < 1 {
+		return
+	}
+
+	f.parallelism = n
+}
+
+// packageCacheKey must include the manifest path: the cached value also
+// carries the suppression verdict, which (via matchesPath) depends on where
+// the package was found, so a bare ecosystem/name/version key would let a
+// second manifest in a multi-manifest scan silently reuse the first
+// manifest's suppression decision for the same package@version.
+func packageCacheKey(pkg *models.Package) string {
+	manifestPath := ""
+	if pkg.Manifest != nil {
+		manifestPath = pkg.Manifest.Path
+	}
+
+	return strings.ToLower(string(pkg.PackageDetails.Ecosystem)) + "|" +
+		pkg.PackageDetails.Name + "|" + pkg.PackageDetails.Version + "|" + manifestPath
 }
 
 func NewEvaluator(name string, ignoreError bool) (Evaluator, error) {
@@ -51,6 +185,7 @@ func NewEvaluator(name string, ignoreError bool) (Evaluator, error) {
 		cel.Variable(filterInputVarScorecard, cel.DynType),
 		cel.Variable(filterInputVarLicenses, cel.DynType),
 		cel.Variable(filterInputVarRoot, cel.DynType),
+		cel.Lib(stdlib.NewLibrary()),
 	)
 
 	if err != nil {
@@ -58,10 +193,12 @@ func NewEvaluator(name string, ignoreError bool) (Evaluator, error) {
 	}
 
 	return &filterEvaluator{
-		name:        name,
-		env:         env,
-		programs:    []*filterProgram{},
-		ignoreError: ignoreError,
+		name:         name,
+		env:          env,
+		programs:     []*filterProgram{},
+		ignoreError:  ignoreError,
+		suppressions: defaultSuppressionList(),
+		parallelism:  defaultEvalParallelism(),
 	}, nil
 }
 
@@ -82,6 +219,7 @@ func (f *filterEvaluator) AddFilter(name, filter string) error {
 
 	f.programs = append(f.programs, &filterProgram{
 		name:    name,
+		expr:    filter,
 		program: prog,
 	})
 
@@ -89,16 +227,147 @@ func (f *filterEvaluator) AddFilter(name, filter string) error {
 }
 
 func (f *filterEvaluator) EvalPackage(pkg *models.Package) (*filterEvaluationResult, error) {
-	filterInput, err := f.buildFilterInput(pkg)
+	serializedInput, expiredSuppressions, err := f.serializedFilterInputForPackage(pkg)
 	if err != nil {
 		return nil, err
 	}
 
-	serializedInput, err := f.serializeFilterInput(filterInput)
-	if err != nil {
+	return f.evalSerialized(serializedInput, expiredSuppressions)
+}
+
+// EvalPackages evaluates every package in pkgs against the added filters
+// using a pool of f.parallelism workers. The per-package jsonpb/JSON
+// serialization - the dominant cost for large manifests - is cached by
+// package identity so repeated ecosystem/name/version combinations across
+// a manifest are only serialized once.
+func (f *filterEvaluator) EvalPackages(ctx context.Context, pkgs []*models.Package) ([]*filterEvaluationResult, error) {
+	results := make([]*filterEvaluationResult, len(pkgs))
+
+	workers := f.parallelism
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(pkgs) {
+		workers = len(pkgs)
+	}
+
+	// runCtx is cancelled either by the caller or by the first worker error,
+	// so the feed loop below never blocks sending to a channel nobody is
+	// going to drain.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indices := make(chan int)
+	var once sync.Once
+	var firstErr error
+
+	setErr := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			// Keep draining indices even after an error so the feed loop
+			// never blocks on a send with no remaining consumer; runCtx
+			// being cancelled stops new work from being fed in anyway.
+			for idx := range indices {
+				res, err := f.EvalPackage(pkgs[idx])
+				if err != nil {
+					if f.ignoreError {
+						continue
+					}
+
+					setErr(err)
+					continue
+				}
+
+				results[idx] = res
+			}
+		}()
+	}
+
+feed:
+	for i := range pkgs {
+		select {
+		case <-runCtx.Done():
+			break feed
+		case indices <- i:
+		}
+	}
+
+	close(indices)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
+	return results, nil
+}
+
+// serializedFilterInputForPackage builds and caches the CEL-serializable
+// filter input for pkg, keyed by ecosystem/name/version/manifest path so
+// that the same package evaluated via multiple filters or across
+// EvalPackages calls only pays the jsonpb/JSON round-trip once, without
+// conflating two manifests' suppression verdicts for the same package.
+func (f *filterEvaluator) serializedFilterInputForPackage(pkg *models.Package) (map[string]interface{}, []*SuppressionEntry, error) {
+	key := packageCacheKey(pkg)
+
+	if cached, ok := f.inputCache.Load(key); ok {
+		c := cached.(*cachedFilterInput)
+		return c.serialized, c.expiredSuppressions, nil
+	}
+
+	built, err := f.buildFilterInput(pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serializedInput, err := f.serializeFilterInput(built.input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotateVulnMetadata(serializedInput, built.vulnStatusByID, built.vulnSeverityByID, built.vulnCvssByID)
+
+	f.inputCache.Store(key, &cachedFilterInput{
+		serialized:          serializedInput,
+		expiredSuppressions: built.expiredSuppressions,
+	})
+
+	for _, entry := range built.expiredSuppressions {
+		recordExpiredSuppression(entry)
+	}
+
+	return serializedInput, built.expiredSuppressions, nil
+}
+
+type cachedFilterInput struct {
+	serialized          map[string]interface{}
+	expiredSuppressions []*SuppressionEntry
+}
+
+// evalSerialized evaluates every added filter program against
+// serializedInput and returns every program that matched, in suite order.
+// It does not stop at the first match: a package can match more than one
+// rule (e.g. both a `warn` and a `block` rule in a filter suite), and the
+// caller needs to see all of them to apply per-rule severity/action
+// correctly instead of one rule's outcome silently winning by file order.
+func (f *filterEvaluator) evalSerialized(serializedInput map[string]interface{}, expiredSuppressions []*SuppressionEntry) (*filterEvaluationResult, error) {
+	var matched []*filterProgram
+
 	for _, prog := range f.programs {
 		out, _, err := prog.program.Eval(map[string]interface{}{
 			filterInputVarRoot:      serializedInput,
@@ -121,21 +390,57 @@ func (f *filterEvaluator) EvalPackage(pkg *models.Package) (*filterEvaluationRes
 
 		if (reflect.TypeOf(out).Kind() == reflect.Bool) &&
 			(reflect.ValueOf(out).Bool()) {
-
-			return &filterEvaluationResult{
-				match:   true,
-				program: prog,
-			}, nil
+			matched = append(matched, prog)
 		}
 	}
 
+	risk := highestVulnRisk(serializedInput)
+
+	if len(matched) == 0 {
+		return &filterEvaluationResult{
+			match:               false,
+			expiredSuppressions: expiredSuppressions,
+			highestVulnRisk:     risk,
+		}, nil
+	}
+
 	return &filterEvaluationResult{
-		match: false,
+		match:               true,
+		program:             matched[0],
+		matches:             matched,
+		expiredSuppressions: expiredSuppressions,
+		highestVulnRisk:     risk,
 	}, nil
 }
 
-// TODO: Fix this JSON round-trip problem by directly configuring CEL env to
-// work with Protobuf messages
+// highestVulnRisk returns the highest severity bucket ("critical", "high",
+// "medium" or "low") that has at least one vulnerability in serializedInput,
+// or an empty string if none do.
+func highestVulnRisk(serializedInput map[string]interface{}) string {
+	vulns, ok := serializedInput[filterInputVarVulns].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	for _, bucket := range []string{"critical", "high", "medium", "low"} {
+		if list, ok := vulns[bucket].([]interface{}); ok && len(list) > 0 {
+			return bucket
+		}
+	}
+
+	return ""
+}
+
+// serializeFilterInput goes through a jsonpb marshal/JSON-unmarshal
+// round-trip rather than evaluating CEL directly against fi as a Protobuf
+// message. Binding the CEL env to the proto types directly would remove
+// this cost altogether, but it's a separate, larger change (the
+// environment, variable declarations and every place that indexes into the
+// serialized map as a plain map[string]interface{}, e.g. annotateVulnMetadata,
+// would all need to move to proto field access). This change only adds the
+// worker pool and per-package cache in serializedFilterInputForPackage to
+// cut the repeated cost of this round-trip for large manifests; the
+// round-trip itself is still tracked as follow-up work.
 func (f *filterEvaluator) serializeFilterInput(fi *filterinput.FilterInput) (map[string]interface{}, error) {
 	var ret map[string]interface{}
 	m := jsonpb.Marshaler{OrigName: true, EnumsAsInts: false, EmitDefaults: true}
@@ -155,7 +460,59 @@ func (f *filterEvaluator) serializeFilterInput(fi *filterinput.FilterInput) (map
 	return ret, nil
 }
 
-func (f *filterEvaluator) buildFilterInput(pkg *models.Package) (*filterinput.FilterInput, error) {
+// filterInputBuildResult bundles the proto-based CEL filter input together
+// with bookkeeping that has no place in the generated FilterInput message:
+// the per-vulnerability disposition, severity and CVSS score (for CEL's
+// `v.status`/`has_severity`/`cvss_at_least`), and suppression entries that
+// matched a live finding but had already expired.
+type filterInputBuildResult struct {
+	input               *filterinput.FilterInput
+	vulnStatusByID      map[string]string
+	vulnSeverityByID    map[string]string
+	vulnCvssByID        map[string]float64
+	expiredSuppressions []*SuppressionEntry
+}
+
+// annotateVulnMetadata stamps "status", "severity" and "cvss" fields onto
+// every serialized vulnerability object so CEL filters (and the
+// has_severity/cvss_at_least stdlib helpers) can read them, e.g.
+// `vulns.critical.exists(v, v.status == "affected")` or
+// `vulns.all.has_severity("CRITICAL")`.
+func annotateVulnMetadata(serialized map[string]interface{}, statusByID map[string]string, severityByID map[string]string, cvssByID map[string]float64) {
+	vulns, ok := serialized[filterInputVarVulns].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, bucket := range []string{"all", "critical", "high", "medium", "low"} {
+		list, ok := vulns[bucket].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, item := range list {
+			v, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			id, _ := v["id"].(string)
+			if status, found := statusByID[id]; found {
+				v["status"] = status
+			}
+
+			if severity, found := severityByID[id]; found {
+				v["severity"] = severity
+			}
+
+			if cvss, found := cvssByID[id]; found {
+				v["cvss"] = cvss
+			}
+		}
+	}
+}
+
+func (f *filterEvaluator) buildFilterInput(pkg *models.Package) (*filterInputBuildResult, error) {
 	fi := filterinput.FilterInput{
 		Pkg: &filterinput.PackageVersion{
 			Ecosystem: strings.ToLower(string(pkg.PackageDetails.Ecosystem)),
@@ -210,24 +567,70 @@ func (f *filterEvaluator) buildFilterInput(pkg *models.Package) (*filterinput.Fi
 		return ""
 	}
 
+	ghsaFilter := func(aliases []string) string {
+		for _, alias := range aliases {
+			if strings.HasPrefix(strings.ToUpper(alias), "GHSA-") {
+				return alias
+			}
+		}
+
+		return ""
+	}
+
+	vulnStatusByID := map[string]string{}
+	vulnSeverityByID := map[string]string{}
+	vulnCvssByID := map[string]float64{}
+	expiredSuppressions := []*SuppressionEntry{}
+	now := time.Now()
+
 	for _, vuln := range utils.SafelyGetValue(insight.Vulnerabilities) {
+		cve := cveFilter(utils.SafelyGetValue(vuln.Aliases))
+		ghsa := ghsaFilter(utils.SafelyGetValue(vuln.Aliases))
+
 		fiv := filterinput.Vulnerability{
 			Id:  utils.SafelyGetValue(vuln.Id),
-			Cve: cveFilter(utils.SafelyGetValue(vuln.Aliases)),
+			Cve: cve,
+		}
+
+		status := string(SuppressionStatusAffected)
+		if entry, expired := f.suppressions.matchVuln(pkg, cve, ghsa, now); entry != nil {
+			status = string(entry.Status)
+
+			if expired {
+				expiredSuppressions = append(expiredSuppressions, entry)
+			} else if entry.Status != SuppressionStatusUnderInvestigation {
+				// Active, non-expired suppression: drop the finding from
+				// the evaluator input entirely, but keep its disposition
+				// around so we can still annotate it below if it ever
+				// surfaces again (e.g. re-added to All by another path).
+				vulnStatusByID[fiv.Id] = status
+				continue
+			}
 		}
 
+		vulnStatusByID[fiv.Id] = status
 		fi.Vulns.All = append(fi.Vulns.All, &fiv)
 
 		risk := insightapi.PackageVulnerabilitySeveritiesRiskUNKNOWN
+		var cvssScore float64
 		for _, s := range utils.SafelyGetValue(vuln.Severities) {
 			sType := utils.SafelyGetValue(s.Type)
 			if (sType == insightapi.PackageVulnerabilitySeveritiesTypeCVSSV3) ||
 				(sType == insightapi.PackageVulnerabilitySeveritiesTypeCVSSV2) {
 				risk = utils.SafelyGetValue(s.Risk)
+				cvssScore = float64(utils.SafelyGetValue(s.Score))
 				break
 			}
 		}
 
+		if risk != insightapi.PackageVulnerabilitySeveritiesRiskUNKNOWN {
+			vulnSeverityByID[fiv.Id] = string(risk)
+		}
+
+		if cvssScore > 0 {
+			vulnCvssByID[fiv.Id] = cvssScore
+		}
+
 		switch risk {
 		case insightapi.PackageVulnerabilitySeveritiesRiskCRITICAL:
 			fi.Vulns.Critical = append(fi.Vulns.Critical, &fiv)
@@ -262,5 +665,11 @@ func (f *filterEvaluator) buildFilterInput(pkg *models.Package) (*filterinput.Fi
 			utils.SafelyGetValue(check.Score)
 	}
 
-	return &fi, nil
+	return &filterInputBuildResult{
+		input:               &fi,
+		vulnStatusByID:      vulnStatusByID,
+		vulnSeverityByID:    vulnSeverityByID,
+		vulnCvssByID:        vulnCvssByID,
+		expiredSuppressions: expiredSuppressions,
+	}, nil
 }
@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/safedep/vet/pkg/models"
+)
+
+func mustEvaluator(t *testing.T, ignoreError bool, name, expr string) Evaluator {
+	t.Helper()
+
+	e, err := NewEvaluator(name, ignoreError)
+	if err != nil {
+		t.Fatalf("NewEvaluator() error = %v", err)
+	}
+
+	if err := e.AddFilter("rule", expr); err != nil {
+		t.Fatalf("AddFilter() error = %v", err)
+	}
+
+	return e
+}
+
+func TestEvalPackages_MatchesInOrder(t *testing.T) {
+	e := mustEvaluator(t, false, "eval-packages-match", `pkg.name == "foo"`)
+
+	pkgs := []*models.Package{
+		testPackage("npm", "foo", "1.0.0", "go.mod"),
+		testPackage("npm", "bar", "1.0.0", "go.mod"),
+		testPackage("npm", "foo", "2.0.0", "go.mod"),
+	}
+
+	results, err := e.EvalPackages(context.Background(), pkgs)
+	if err != nil {
+		t.Fatalf("EvalPackages() error = %v", err)
+	}
+
+	if len(results) != len(pkgs) {
+		t.Fatalf("got %d results, want %d", len(results), len(pkgs))
+	}
+
+	want := []bool{true, false, true}
+	for i, w := range want {
+		if results[i].Matched() != w {
+			t.Errorf("result %d: Matched() = %v, want %v", i, results[i].Matched(), w)
+		}
+	}
+}
+
+func TestEvalPackages_IgnoreErrorSkipsFailures(t *testing.T) {
+	// Indexing an empty list is a CEL runtime error - every package built
+	// here has no vulnerabilities, so vulns.all[0] always fails to evaluate.
+	e := mustEvaluator(t, true, "eval-packages-ignore-error", `vulns.all[0] == null`)
+
+	pkgs := []*models.Package{
+		testPackage("npm", "foo", "1.0.0", "go.mod"),
+		testPackage("npm", "bar", "1.0.0", "go.mod"),
+	}
+
+	results, err := e.EvalPackages(context.Background(), pkgs)
+	if err != nil {
+		t.Fatalf("EvalPackages() error = %v", err)
+	}
+
+	for i, r := range results {
+		if r != nil {
+			t.Errorf("result %d: expected no result for a package whose only filter errored, got %+v", i, r)
+		}
+	}
+}
+
+func TestEvalPackages_ErrorDoesNotDeadlock(t *testing.T) {
+	e := mustEvaluator(t, false, "eval-packages-error", `vulns.all[0] == null`)
+	e.SetParallelism(2)
+
+	pkgs := make([]*models.Package, 0, 10)
+	for i := 0; i < 10; i++ {
+		pkgs = append(pkgs, testPackage("npm", "foo", "1.0.0", "go.mod"))
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := e.EvalPackages(context.Background(), pkgs)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error from the failing filter")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("EvalPackages() deadlocked instead of returning the worker's error")
+	}
+}
+
+func TestEvalPackages_ContextCancellation(t *testing.T) {
+	e := mustEvaluator(t, false, "eval-packages-cancel", `pkg.name == "foo"`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pkgs := []*models.Package{
+		testPackage("npm", "foo", "1.0.0", "go.mod"),
+		testPackage("npm", "bar", "1.0.0", "go.mod"),
+	}
+
+	if _, err := e.EvalPackages(ctx, pkgs); err == nil {
+		t.Fatal("expected a context cancellation error")
+	}
+}
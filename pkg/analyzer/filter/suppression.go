@@ -0,0 +1,323 @@
+package filter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/safedep/vet/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressionStatus mirrors the disposition recorded by whoever triaged a
+// finding, matching the vocabulary used by OSV/GHSA style advisories.
+type SuppressionStatus string
+
+const (
+	SuppressionStatusNotAffected        SuppressionStatus = "not_affected"
+	SuppressionStatusWillNotFix         SuppressionStatus = "will_not_fix"
+	SuppressionStatusFixed              SuppressionStatus = "fixed"
+	SuppressionStatusUnderInvestigation SuppressionStatus = "under_investigation"
+	SuppressionStatusAffected           SuppressionStatus = "affected"
+)
+
+// SuppressionEntry is a single suppressed/allow-listed record as authored in
+// a suppression list YAML file. A CVE/GHSA ID suppresses a specific
+// vulnerability; a bare package@version suppresses everything reported
+// against that package.
+type SuppressionEntry struct {
+	CVE           string            `yaml:"cve,omitempty"`
+	GHSA          string            `yaml:"ghsa,omitempty"`
+	Package       string            `yaml:"package,omitempty"`
+	Version       string            `yaml:"version,omitempty"`
+	Ecosystem     string            `yaml:"ecosystem,omitempty"`
+	PathGlob      string            `yaml:"path,omitempty"`
+	Status        SuppressionStatus `yaml:"status"`
+	Justification string            `yaml:"justification"`
+	ExpiresAt     *time.Time        `yaml:"expires_at,omitempty"`
+}
+
+func (e *SuppressionEntry) expired(now time.Time) bool {
+	return e.ExpiresAt != nil && e.ExpiresAt.Before(now)
+}
+
+func (e *SuppressionEntry) matchesVuln(cve, ghsa string) bool {
+	if e.CVE != "" && strings.EqualFold(e.CVE, cve) {
+		return true
+	}
+
+	if e.GHSA != "" && strings.EqualFold(e.GHSA, ghsa) {
+		return true
+	}
+
+	return false
+}
+
+func (e *SuppressionEntry) matchesPackage(pkg *models.Package) bool {
+	if e.Package == "" {
+		return false
+	}
+
+	if e.Ecosystem != "" && !strings.EqualFold(e.Ecosystem, string(pkg.PackageDetails.Ecosystem)) {
+		return false
+	}
+
+	if e.Version != "" && e.Version != pkg.PackageDetails.Version {
+		return false
+	}
+
+	return strings.EqualFold(e.Package, pkg.PackageDetails.Name)
+}
+
+// matchesPath returns true if the entry's PathGlob (if any) matches pkg's
+// manifest path. An entry without a PathGlob applies everywhere, matching
+// the "optional" scope semantics of the other scoping fields.
+func (e *SuppressionEntry) matchesPath(pkg *models.Package) bool {
+	if e.PathGlob == "" {
+		return true
+	}
+
+	if pkg.Manifest == nil {
+		return false
+	}
+
+	matched, err := filepath.Match(e.PathGlob, pkg.Manifest.Path)
+	if err != nil {
+		return false
+	}
+
+	return matched
+}
+
+// SuppressionList is a structured CVE/GHSA/package allow-list loaded from
+// YAML. It is consulted by the evaluator to drop triaged findings from
+// filter input while still surfacing their disposition to CEL expressions.
+type SuppressionList struct {
+	Entries []*SuppressionEntry `yaml:"suppressions"`
+}
+
+// LoadSuppressionList reads a suppression list from a YAML file on disk.
+func LoadSuppressionList(path string) (*SuppressionList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suppression list %s: %w", path, err)
+	}
+
+	var list SuppressionList
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse suppression list %s: %w", path, err)
+	}
+
+	return &list, nil
+}
+
+// matchVuln returns the entry suppressing the given vulnerability, and
+// whether that entry has expired as of now. A nil entry means the
+// vulnerability is not suppressed.
+//
+// An entry scopes itself along two independent dimensions: the vulnerability
+// (CVE/GHSA) and the package (package/version/ecosystem). When an entry sets
+// only one dimension, that dimension alone decides the match - a bare
+// package@version entry suppresses every vulnerability on that package, and
+// a bare CVE suppresses that CVE on any package. But when an entry sets
+// both (as exceptionToSuppressions does for a CVE-scoped exception review),
+// both must match: otherwise a package-scoped entry that also happens to
+// name a CVE would match every vulnerability on the package instead of just
+// the one reviewed.
+func (sl *SuppressionList) matchVuln(pkg *models.Package, cve, ghsa string, now time.Time) (*SuppressionEntry, bool) {
+	if sl == nil {
+		return nil, false
+	}
+
+	for _, entry := range sl.Entries {
+		if !entry.matchesPath(pkg) {
+			continue
+		}
+
+		hasVulnScope := entry.CVE != "" || entry.GHSA != ""
+		hasPackageScope := entry.Package != ""
+
+		switch {
+		case hasVulnScope && hasPackageScope:
+			if !entry.matchesVuln(cve, ghsa) || !entry.matchesPackage(pkg) {
+				continue
+			}
+		case hasVulnScope:
+			if !entry.matchesVuln(cve, ghsa) {
+				continue
+			}
+		case hasPackageScope:
+			if !entry.matchesPackage(pkg) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		return entry, entry.expired(now)
+	}
+
+	return nil, false
+}
+
+var (
+	globalSuppressionsMu sync.Mutex
+	globalSuppressions   *SuppressionList
+
+	expiredSuppressionsMu sync.Mutex
+	expiredSuppressions   []*SuppressionEntry
+)
+
+// SetSuppressionList configures the suppression list that newly created
+// evaluators will use by default, loading it from the given YAML path. It
+// is intended to be called once during command startup, e.g. from
+// `vet query --suppressions`.
+func SetSuppressionList(path string) error {
+	list, err := LoadSuppressionList(path)
+	if err != nil {
+		return err
+	}
+
+	globalSuppressionsMu.Lock()
+	defer globalSuppressionsMu.Unlock()
+
+	globalSuppressions = list
+	return nil
+}
+
+// ExceptionRecord is a single generated exception: a package (optionally
+// scoped to the filter rule that flagged it, and to the specific CVEs or
+// licenses reviewed) that a human has reviewed and accepted, along with why.
+// It is the canonical shape for the --exceptions-generate YAML file, shared
+// between ConsultExceptions here and analyzer.ExceptionsGenerator (which
+// writes it) so the two never drift apart on which fields round-trip.
+type ExceptionRecord struct {
+	Hash          string    `yaml:"hash"`
+	Package       string    `yaml:"package"`
+	Version       string    `yaml:"version"`
+	Ecosystem     string    `yaml:"ecosystem"`
+	Rule          string    `yaml:"rule,omitempty"`
+	CVEs          []string  `yaml:"cves,omitempty"`
+	Licenses      []string  `yaml:"licenses,omitempty"`
+	Justification string    `yaml:"justification"`
+	Author        string    `yaml:"author,omitempty"`
+	CreatedAt     time.Time `yaml:"created_at"`
+	ExpiresAt     time.Time `yaml:"expires_at"`
+}
+
+// ExceptionsDocument is the top-level --exceptions-generate YAML document.
+type ExceptionsDocument struct {
+	Exceptions []*ExceptionRecord `yaml:"exceptions"`
+}
+
+// ExceptionHash derives the stable identity hash used to recognize the same
+// exception (by ecosystem/name/version/rule) across repeated runs.
+func ExceptionHash(ecosystem, name, version, rule string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(ecosystem) + "|" + name + "|" + version + "|" + rule))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// ConsultExceptions loads an exceptions file generated by
+// analyzer.ExceptionsGenerator and merges its records into the default
+// suppression list as `will_not_fix` entries, so a package that was already
+// reviewed and accepted doesn't get re-flagged by a later `--filter` or
+// `--filter-suite` run until its exception expires. Safe to call alongside
+// SetSuppressionList, in either order.
+//
+// An exception's CVEs (if any) are carried through as individual CVE-scoped
+// suppression entries rather than one broad package@version entry, so a
+// rule-scoped review of one finding doesn't also silently suppress an
+// unrelated, never-reviewed CVE on the same package.
+func ConsultExceptions(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read exceptions file %s: %w", path, err)
+	}
+
+	var doc ExceptionsDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse exceptions file %s: %w", path, err)
+	}
+
+	globalSuppressionsMu.Lock()
+	defer globalSuppressionsMu.Unlock()
+
+	if globalSuppressions == nil {
+		globalSuppressions = &SuppressionList{}
+	}
+
+	for _, exception := range doc.Exceptions {
+		globalSuppressions.Entries = append(globalSuppressions.Entries, exceptionToSuppressions(exception)...)
+	}
+
+	return nil
+}
+
+// exceptionToSuppressions converts a reviewed exception record into the
+// suppression entries it should contribute. An exception with reviewed CVEs
+// only suppresses those CVEs on the exact package@version it was reviewed
+// for; an exception with no CVEs (e.g. a license-only review) falls back to
+// suppressing the whole package@version, matching the prior behavior.
+func exceptionToSuppressions(exception *ExceptionRecord) []*SuppressionEntry {
+	var expiresAt *time.Time
+	if !exception.ExpiresAt.IsZero() {
+		t := exception.ExpiresAt
+		expiresAt = &t
+	}
+
+	if len(exception.CVEs) == 0 {
+		return []*SuppressionEntry{{
+			Package:       exception.Package,
+			Version:       exception.Version,
+			Ecosystem:     exception.Ecosystem,
+			Status:        SuppressionStatusWillNotFix,
+			Justification: exception.Justification,
+			ExpiresAt:     expiresAt,
+		}}
+	}
+
+	entries := make([]*SuppressionEntry, 0, len(exception.CVEs))
+	for _, cve := range exception.CVEs {
+		entries = append(entries, &SuppressionEntry{
+			CVE:           cve,
+			Package:       exception.Package,
+			Version:       exception.Version,
+			Ecosystem:     exception.Ecosystem,
+			Status:        SuppressionStatusWillNotFix,
+			Justification: exception.Justification,
+			ExpiresAt:     expiresAt,
+		})
+	}
+
+	return entries
+}
+
+func defaultSuppressionList() *SuppressionList {
+	globalSuppressionsMu.Lock()
+	defer globalSuppressionsMu.Unlock()
+
+	return globalSuppressions
+}
+
+func recordExpiredSuppression(entry *SuppressionEntry) {
+	expiredSuppressionsMu.Lock()
+	defer expiredSuppressionsMu.Unlock()
+
+	expiredSuppressions = append(expiredSuppressions, entry)
+}
+
+// ExpiredSuppressions returns every suppression entry that was matched
+// against a live finding but had already lapsed, accumulated across all
+// evaluators in this process. Callers (e.g. the query command) can use
+// this to fail CI when a suppression needs renewal.
+func ExpiredSuppressions() []*SuppressionEntry {
+	expiredSuppressionsMu.Lock()
+	defer expiredSuppressionsMu.Unlock()
+
+	return append([]*SuppressionEntry{}, expiredSuppressions...)
+}
@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/safedep/vet/pkg/models"
+)
+
+func testPackage(ecosystem, name, version, manifestPath string) *models.Package {
+	return &models.Package{
+		PackageDetails: models.PackageDetails{
+			Ecosystem: ecosystem,
+			Name:      name,
+			Version:   version,
+		},
+		Manifest: &models.PackageManifest{
+			Path: manifestPath,
+		},
+	}
+}
+
+func TestSuppressionList_MatchVuln_PathGlob(t *testing.T) {
+	now := time.Now()
+
+	cases := []struct {
+		name    string
+		entry   *SuppressionEntry
+		pkg     *models.Package
+		wantHit bool
+	}{
+		{
+			name:    "path glob matches",
+			entry:   &SuppressionEntry{CVE: "CVE-2024-0001", PathGlob: "services/*/go.mod"},
+			pkg:     testPackage("npm", "lodash", "4.17.21", "services/api/go.mod"),
+			wantHit: true,
+		},
+		{
+			name:    "path glob does not match",
+			entry:   &SuppressionEntry{CVE: "CVE-2024-0001", PathGlob: "services/*/go.mod"},
+			pkg:     testPackage("npm", "lodash", "4.17.21", "tools/scan/go.mod"),
+			wantHit: false,
+		},
+		{
+			name:    "no path glob applies everywhere",
+			entry:   &SuppressionEntry{CVE: "CVE-2024-0001"},
+			pkg:     testPackage("npm", "lodash", "4.17.21", "tools/scan/go.mod"),
+			wantHit: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := &SuppressionList{Entries: []*SuppressionEntry{tc.entry}}
+
+			entry, _ := list.matchVuln(tc.pkg, "CVE-2024-0001", "", now)
+			if (entry != nil) != tc.wantHit {
+				t.Errorf("matchVuln() hit = %v, want %v", entry != nil, tc.wantHit)
+			}
+		})
+	}
+}
+
+func TestSuppressionList_MatchVuln_Expiry(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	list := &SuppressionList{
+		Entries: []*SuppressionEntry{
+			{CVE: "CVE-2024-0002", ExpiresAt: &past},
+		},
+	}
+
+	entry, expired := list.matchVuln(testPackage("npm", "lodash", "4.17.21", "go.mod"), "CVE-2024-0002", "", now)
+	if entry == nil {
+		t.Fatal("expected a matching entry")
+	}
+
+	if !expired {
+		t.Error("expected the entry to be reported as expired")
+	}
+}
+
+func TestSuppressionList_MatchVuln_BothDimensionsRequireBoth(t *testing.T) {
+	now := time.Now()
+	pkg := testPackage("npm", "lodash", "4.17.21", "go.mod")
+
+	// An entry scoping both a CVE and a package must require both to match -
+	// matchesPackage alone (ignoring the CVE) must not be enough.
+	entry := &SuppressionEntry{CVE: "CVE-2024-0001", Package: "lodash", Version: "4.17.21", Ecosystem: "npm"}
+	list := &SuppressionList{Entries: []*SuppressionEntry{entry}}
+
+	if got, _ := list.matchVuln(pkg, "CVE-2099-9999", "", now); got != nil {
+		t.Error("entry scoping both CVE and package matched a different CVE on the same package")
+	}
+
+	if got, _ := list.matchVuln(pkg, "CVE-2024-0001", "", now); got == nil {
+		t.Error("entry scoping both CVE and package did not match when both match")
+	}
+
+	other := testPackage("npm", "not-lodash", "4.17.21", "go.mod")
+	if got, _ := list.matchVuln(other, "CVE-2024-0001", "", now); got != nil {
+		t.Error("entry scoping both CVE and package matched a different package with the same CVE")
+	}
+}
+
+func TestExceptionToSuppressions_ScopesToReviewedCVEs(t *testing.T) {
+	record := &ExceptionRecord{
+		Package:   "lodash",
+		Version:   "4.17.21",
+		Ecosystem: "npm",
+		CVEs:      []string{"CVE-2024-0001", "CVE-2024-0002"},
+	}
+
+	entries := exceptionToSuppressions(record)
+	if len(entries) != 2 {
+		t.Fatalf("expected one suppression entry per reviewed CVE, got %d", len(entries))
+	}
+
+	for i, cve := range record.CVEs {
+		if entries[i].CVE != cve {
+			t.Errorf("entry %d: got CVE %q, want %q", i, entries[i].CVE, cve)
+		}
+	}
+
+	// A CVE that was never reviewed must not be suppressed by this record -
+	// this is the bug the maintainer flagged: a rule-scoped exception must
+	// not silently suppress an unrelated, never-reviewed CVE.
+	list := &SuppressionList{Entries: entries}
+	if entry, _ := list.matchVuln(testPackage("npm", "lodash", "4.17.21", "go.mod"), "CVE-2099-9999", "", time.Now()); entry != nil {
+		t.Error("unreviewed CVE must not be suppressed")
+	}
+}
+
+func TestExceptionToSuppressions_FallsBackToPackageScope(t *testing.T) {
+	record := &ExceptionRecord{
+		Package:   "lodash",
+		Version:   "4.17.21",
+		Ecosystem: "npm",
+		Licenses:  []string{"GPL-3.0"},
+	}
+
+	entries := exceptionToSuppressions(record)
+	if len(entries) != 1 {
+		t.Fatalf("expected a single package-scoped entry, got %d", len(entries))
+	}
+
+	if entries[0].Package != "lodash" || entries[0].CVE != "" {
+		t.Errorf("expected a broad package@version entry, got %+v", entries[0])
+	}
+}
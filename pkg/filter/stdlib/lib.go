@@ -0,0 +1,284 @@
+// Package stdlib provides a library of CEL functions and macros for the
+// vulnerability, license and scorecard predicates that filter-suite authors
+// otherwise have to re-derive as ad-hoc boolean arithmetic.
+package stdlib
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// copyleftLicenses is a conservative, non-exhaustive list of SPDX
+// identifiers considered copyleft for the purpose of `licenses.is_copyleft`.
+var copyleftLicenses = map[string]bool{
+	"GPL-2.0":  true,
+	"GPL-2.0+": true,
+	"GPL-3.0":  true,
+	"GPL-3.0+": true,
+	"AGPL-3.0": true,
+	"LGPL-2.1": true,
+	"LGPL-3.0": true,
+	"MPL-2.0":  true,
+	"EPL-2.0":  true,
+	"CDDL-1.0": true,
+	"EUPL-1.2": true,
+}
+
+// mitCompatibleLicenses approximates which SPDX identifiers can be safely
+// combined with an MIT-licensed codebase without triggering copyleft
+// obligations.
+var mitCompatibleLicenses = map[string]bool{
+	"MIT":          true,
+	"BSD-2-Clause": true,
+	"BSD-3-Clause": true,
+	"Apache-2.0":   true,
+	"ISC":          true,
+	"0BSD":         true,
+	"Unlicense":    true,
+}
+
+// library implements cel.Library, bundling every function this package
+// registers so callers can add them to a cel.Env with a single cel.Lib()
+// option.
+type library struct{}
+
+// NewLibrary returns the stdlib CEL library. It is registered by default on
+// the environment built in filter.NewEvaluator.
+func NewLibrary() cel.Library {
+	return &library{}
+}
+
+func (*library) LibraryName() string {
+	return "safedep.vet.filter.stdlib"
+}
+
+// CompileOptions registers every helper function. Note there is no
+// `pkg.age_days()`: the filter input serialized from insight data carries no
+// package publish timestamp, so a function reading it would always return
+// -1 regardless of the package - add it once that data is available.
+func (l *library) CompileOptions() []cel.EnvOption {
+	return []cel.EnvOption{
+		cel.Function("has_severity",
+			cel.MemberOverload("vulns_has_severity",
+				[]*cel.Type{cel.DynType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(hasSeverity)),
+		),
+		cel.Function("cvss_at_least",
+			cel.MemberOverload("vulns_cvss_at_least",
+				[]*cel.Type{cel.DynType, cel.DoubleType}, cel.BoolType,
+				cel.BinaryBinding(cvssAtLeast)),
+		),
+		cel.Function("cve_matches",
+			cel.MemberOverload("vulns_cve_matches",
+				[]*cel.Type{cel.DynType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(cveMatches)),
+		),
+		cel.Function("any_of",
+			cel.MemberOverload("licenses_any_of",
+				[]*cel.Type{cel.DynType, cel.ListType(cel.StringType)}, cel.BoolType,
+				cel.BinaryBinding(licensesAnyOf)),
+		),
+		cel.Function("is_copyleft",
+			cel.MemberOverload("licenses_is_copyleft",
+				[]*cel.Type{cel.DynType}, cel.BoolType,
+				cel.UnaryBinding(licensesIsCopyleft)),
+		),
+		cel.Function("spdx_compatible_with",
+			cel.MemberOverload("licenses_spdx_compatible_with",
+				[]*cel.Type{cel.DynType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(licensesSpdxCompatibleWith)),
+		),
+		cel.Function("check",
+			cel.MemberOverload("scorecard_check",
+				[]*cel.Type{cel.DynType, cel.StringType}, cel.DoubleType,
+				cel.BinaryBinding(scorecardCheck)),
+		),
+		cel.Function("github",
+			cel.MemberOverload("projects_github",
+				[]*cel.Type{cel.DynType}, cel.DynType,
+				cel.UnaryBinding(projectsGitHub)),
+		),
+	}
+}
+
+func (l *library) ProgramOptions() []cel.ProgramOption {
+	return []cel.ProgramOption{}
+}
+
+// vulnList extracts the list of vulnerability maps from a `vulns.*` value,
+// e.g. vulns.critical or vulns.all as produced by the filter input
+// serializer.
+func vulnList(val ref.Val) []map[string]interface{} {
+	raw, _ := val.Value().([]interface{})
+
+	vulns := make([]map[string]interface{}, 0, len(raw))
+	for _, item := range raw {
+		if m, ok := item.(map[string]interface{}); ok {
+			vulns = append(vulns, m)
+		}
+	}
+
+	return vulns
+}
+
+func hasSeverity(lhs, rhs ref.Val) ref.Val {
+	severity, ok := rhs.Value().(string)
+	if !ok {
+		return types.Bool(false)
+	}
+
+	severity = strings.ToUpper(severity)
+	for _, v := range vulnList(lhs) {
+		if sev, ok := v["severity"].(string); ok && strings.ToUpper(sev) == severity {
+			return types.Bool(true)
+		}
+	}
+
+	return types.Bool(false)
+}
+
+func cvssAtLeast(lhs, rhs ref.Val) ref.Val {
+	threshold, ok := rhs.Value().(float64)
+	if !ok {
+		return types.Bool(false)
+	}
+
+	for _, v := range vulnList(lhs) {
+		score, ok := v["cvss"].(float64)
+		if ok && score >= threshold {
+			return types.Bool(true)
+		}
+	}
+
+	return types.Bool(false)
+}
+
+func cveMatches(lhs, rhs ref.Val) ref.Val {
+	pattern, ok := rhs.Value().(string)
+	if !ok {
+		return types.Bool(false)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return types.Bool(false)
+	}
+
+	for _, v := range vulnList(lhs) {
+		if cve, ok := v["cve"].(string); ok && re.MatchString(cve) {
+			return types.Bool(true)
+		}
+	}
+
+	return types.Bool(false)
+}
+
+func stringList(val ref.Val) []string {
+	raw, ok := val.Value().([]interface{})
+	if !ok {
+		return nil
+	}
+
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+func licensesAnyOf(lhs, rhs ref.Val) ref.Val {
+	want := stringList(rhs)
+	have := stringList(lhs)
+
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(w, h) {
+				return types.Bool(true)
+			}
+		}
+	}
+
+	return types.Bool(false)
+}
+
+func licensesIsCopyleft(val ref.Val) ref.Val {
+	for _, lic := range stringList(val) {
+		if copyleftLicenses[lic] {
+			return types.Bool(true)
+		}
+	}
+
+	return types.Bool(false)
+}
+
+func licensesSpdxCompatibleWith(lhs, rhs ref.Val) ref.Val {
+	with, ok := rhs.Value().(string)
+	if !ok {
+		return types.Bool(false)
+	}
+
+	// Today only compatibility with MIT-style permissive bases is
+	// supported, which covers the common "can I vendor this" question.
+	if !strings.EqualFold(with, "MIT") {
+		return types.Bool(false)
+	}
+
+	for _, lic := range stringList(lhs) {
+		if !mitCompatibleLicenses[lic] {
+			return types.Bool(false)
+		}
+	}
+
+	return types.Bool(true)
+}
+
+func scorecardCheck(lhs, rhs ref.Val) ref.Val {
+	name, ok := rhs.Value().(string)
+	if !ok {
+		return types.Double(0)
+	}
+
+	scores, ok := lhs.Value().(map[string]interface{})
+	if !ok {
+		return types.Double(0)
+	}
+
+	checks, ok := scores["scores"].(map[string]interface{})
+	if !ok {
+		return types.Double(0)
+	}
+
+	score, ok := checks[name].(float64)
+	if !ok {
+		return types.Double(0)
+	}
+
+	return types.Double(score)
+}
+
+func projectsGitHub(val ref.Val) ref.Val {
+	raw, ok := val.Value().([]interface{})
+	if !ok {
+		return types.NullValue
+	}
+
+	for _, item := range raw {
+		project, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if tp, ok := project["type"].(string); ok && strings.EqualFold(tp, "GITHUB") {
+			return types.NewDynamicMap(types.DefaultTypeAdapter, project)
+		}
+	}
+
+	return types.NullValue
+}
@@ -0,0 +1,68 @@
+package stdlib
+
+import (
+	"testing"
+
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+func vulnsVal(vulns []map[string]interface{}) ref.Val {
+	raw := make([]interface{}, 0, len(vulns))
+	for _, v := range vulns {
+		raw = append(raw, v)
+	}
+
+	return types.DefaultTypeAdapter.NativeToValue(raw)
+}
+
+func TestHasSeverity(t *testing.T) {
+	vulns := vulnsVal([]map[string]interface{}{
+		{"id": "GHSA-1", "severity": "HIGH"},
+		{"id": "GHSA-2", "severity": "CRITICAL"},
+	})
+
+	if got := hasSeverity(vulns, types.String("CRITICAL")); got != types.Bool(true) {
+		t.Errorf("hasSeverity(CRITICAL) = %v, want true", got)
+	}
+
+	if got := hasSeverity(vulns, types.String("LOW")); got != types.Bool(false) {
+		t.Errorf("hasSeverity(LOW) = %v, want false", got)
+	}
+}
+
+func TestCvssAtLeast(t *testing.T) {
+	vulns := vulnsVal([]map[string]interface{}{
+		{"id": "GHSA-1", "cvss": 7.5},
+	})
+
+	if got := cvssAtLeast(vulns, types.Double(7.0)); got != types.Bool(true) {
+		t.Errorf("cvssAtLeast(7.0) = %v, want true", got)
+	}
+
+	if got := cvssAtLeast(vulns, types.Double(9.0)); got != types.Bool(false) {
+		t.Errorf("cvssAtLeast(9.0) = %v, want false", got)
+	}
+}
+
+func TestLicensesIsCopyleft(t *testing.T) {
+	copyleft := types.DefaultTypeAdapter.NativeToValue([]interface{}{"GPL-3.0"})
+	permissive := types.DefaultTypeAdapter.NativeToValue([]interface{}{"MIT", "Apache-2.0"})
+
+	if got := licensesIsCopyleft(copyleft); got != types.Bool(true) {
+		t.Errorf("licensesIsCopyleft(GPL-3.0) = %v, want true", got)
+	}
+
+	if got := licensesIsCopyleft(permissive); got != types.Bool(false) {
+		t.Errorf("licensesIsCopyleft(MIT, Apache-2.0) = %v, want false", got)
+	}
+}
+
+func TestLicensesAnyOf(t *testing.T) {
+	have := types.DefaultTypeAdapter.NativeToValue([]interface{}{"Apache-2.0"})
+	want := types.DefaultTypeAdapter.NativeToValue([]interface{}{"gpl-3.0", "apache-2.0"})
+
+	if got := licensesAnyOf(have, want); got != types.Bool(true) {
+		t.Errorf("licensesAnyOf(Apache-2.0) = %v, want true", got)
+	}
+}
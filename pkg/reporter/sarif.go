@@ -0,0 +1,168 @@
+package reporter
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/safedep/vet/pkg/models"
+)
+
+const (
+	sarifSchemaURL  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion    = "2.1.0"
+	sarifDriverName = "safedep/vet"
+)
+
+// SarifReportingConfig configures the SARIF reporter.
+type SarifReportingConfig struct {
+	// Path to write the SARIF log to.
+	Path string
+}
+
+// SarifMatch is a single filter match to be rendered as a SARIF result.
+// RuleID and Expression identify the filter that matched (the suite's rule
+// name and its raw CEL expression respectively); Severity is the highest
+// vulnerability risk found on the package ("critical", "high", ...); Path
+// is the manifest file the package was found in.
+type SarifMatch struct {
+	RuleID     string
+	Expression string
+	Severity   string
+	Path       string
+	PackageRef string
+}
+
+// SarifReporter renders filter-suite matches as a SARIF 2.1.0 log so that
+// `vet query` results can be consumed by GitHub Advanced Security code
+// scanning and other SARIF aware dashboards. It is exported (rather than
+// the package-private naming other reporters use) so callers that need to
+// record matches - analyzer.CelFilterSuiteAnalyzer, via AddMatch - can hold
+// a typed reference to it instead of going through the narrower Reporter
+// interface.
+type SarifReporter struct {
+	config SarifReportingConfig
+
+	mu      sync.Mutex
+	results []sarifResult
+}
+
+// NewSarifReporter creates a reporter that writes a SARIF log to
+// config.Path when Finish is called.
+func NewSarifReporter(config SarifReportingConfig) (*SarifReporter, error) {
+	return &SarifReporter{
+		config:  config,
+		results: []sarifResult{},
+	}, nil
+}
+
+func (r *SarifReporter) Name() string {
+	return "SARIF Reporter"
+}
+
+func (r *SarifReporter) AddManifest(manifest *models.PackageManifest) {
+	// SARIF results are recorded per-match via AddMatch, not per-manifest.
+}
+
+// AddMatch records a filter match to be emitted as a SARIF result on
+// Finish.
+func (r *SarifReporter) AddMatch(match SarifMatch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, sarifResult{
+		RuleID: match.RuleID,
+		Level:  sarifLevelFromSeverity(match.Severity),
+		Message: sarifMessage{
+			Text: "vet filter `" + match.Expression + "` matched " + match.PackageRef,
+		},
+		Locations: []sarifLocation{
+			{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: match.Path},
+				},
+			},
+		},
+	})
+}
+
+func (r *SarifReporter) Finish() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log := sarifLog{
+		Schema:  sarifSchemaURL,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{Name: sarifDriverName},
+				},
+				Results: r.results,
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.config.Path, data, 0644)
+}
+
+func sarifLevelFromSeverity(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// The following types are a minimal subset of the SARIF 2.1.0 object model
+// needed to represent filter-suite matches; see the schema referenced by
+// sarifSchemaURL for the full spec.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
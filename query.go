@@ -1,10 +1,14 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/safedep/dry/utils"
 	"github.com/safedep/vet/pkg/analyzer"
+	"github.com/safedep/vet/pkg/analyzer/filter"
 	"github.com/safedep/vet/pkg/readers"
 	"github.com/safedep/vet/pkg/reporter"
 	"github.com/safedep/vet/pkg/scanner"
@@ -12,16 +16,20 @@ import (
 )
 
 var (
-	queryFilterExpression    string
-	queryFilterSuiteFile     string
-	queryFilterFailOnMatch   bool
-	queryLoadDirectory       string
-	queryEnableConsoleReport bool
-	queryEnableSummaryReport bool
-	queryMarkdownReportPath  string
-	queryExceptionsFile      string
-	queryExceptionsTill      string
-	queryExceptionsFilter    string
+	queryFilterExpression        string
+	queryFilterSuiteFile         string
+	queryFilterFailOnMatch       bool
+	queryLoadDirectory           string
+	queryEnableConsoleReport     bool
+	queryEnableSummaryReport     bool
+	queryMarkdownReportPath      string
+	queryExceptionsFile          string
+	queryExceptionsTill          string
+	queryExceptionsFilter        string
+	queryExceptionsJustification string
+	querySuppressionsFile        string
+	querySarifReportPath         string
+	queryPolicyReportPath        string
 
 	queryDefaultExceptionExpiry = time.Now().Add(90 * 24 * time.Hour)
 )
@@ -51,12 +59,20 @@ func newQueryCommand() *cobra.Command {
 		"Generated exceptions are valid till")
 	cmd.Flags().StringVarP(&queryExceptionsFilter, "exceptions-filter", "", "",
 		"Generate exception records for packages matching filter")
+	cmd.Flags().StringVarP(&queryExceptionsJustification, "exceptions-justification", "", "",
+		"Justification to use for every generated exception record (skips the interactive prompt)")
+	cmd.Flags().StringVarP(&queryPolicyReportPath, "policy-report", "", "",
+		"Write a machine readable policy gate report (JSON) from the filter suite run")
+	cmd.Flags().StringVarP(&querySuppressionsFile, "suppressions", "", "",
+		"Suppress vulnerabilities listed in this YAML file during filter evaluation")
 	cmd.Flags().BoolVarP(&queryEnableConsoleReport, "report-console", "", false,
 		"Minimal summary of package manifest")
 	cmd.Flags().BoolVarP(&queryEnableSummaryReport, "report-summary", "", false,
 		"Show an actionable summary based on scan data")
 	cmd.Flags().StringVarP(&queryMarkdownReportPath, "report-markdown", "", "",
 		"Generate markdown report to file")
+	cmd.Flags().StringVarP(&querySarifReportPath, "report-sarif", "", "",
+		"Generate SARIF report of filter matches to file")
 	return cmd
 }
 
@@ -77,6 +93,20 @@ func internalStartQuery() error {
 
 	readerList = append(readerList, reader)
 
+	var filterSuiteAnalyzer *analyzer.CelFilterSuiteAnalyzer
+
+	if !utils.IsEmptyString(querySuppressionsFile) {
+		if err := filter.SetSuppressionList(querySuppressionsFile); err != nil {
+			return err
+		}
+	}
+
+	if !utils.IsEmptyString(queryExceptionsFile) {
+		if err := filter.ConsultExceptions(queryExceptionsFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
 	if !utils.IsEmptyString(queryFilterExpression) {
 		task, err := analyzer.NewCelFilterAnalyzer(queryFilterExpression,
 			queryFilterFailOnMatch)
@@ -94,14 +124,16 @@ func internalStartQuery() error {
 			return err
 		}
 
+		filterSuiteAnalyzer = task
 		analyzers = append(analyzers, task)
 	}
 
 	if !utils.IsEmptyString(queryExceptionsFile) {
 		task, err := analyzer.NewExceptionsGenerator(analyzer.ExceptionsGeneratorConfig{
-			Path:      queryExceptionsFile,
-			ExpiresOn: queryExceptionsTill,
-			Filter:    queryExceptionsFilter,
+			Path:          queryExceptionsFile,
+			ExpiresOn:     queryExceptionsTill,
+			Filter:        queryExceptionsFilter,
+			Justification: queryExceptionsJustification,
 		})
 
 		if err != nil {
@@ -141,10 +173,57 @@ func internalStartQuery() error {
 		reporters = append(reporters, rp)
 	}
 
+	if !utils.IsEmptyString(querySarifReportPath) {
+		rp, err := reporter.NewSarifReporter(reporter.SarifReportingConfig{
+			Path: querySarifReportPath,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if filterSuiteAnalyzer != nil {
+			filterSuiteAnalyzer.SetSarifRecorder(rp)
+		}
+
+		reporters = append(reporters, rp)
+	}
+
 	pmScanner := scanner.NewPackageManifestScanner(scanner.Config{
 		TransitiveAnalysis: false,
 	}, readerList, enrichers, analyzers, reporters)
 
 	redirectLogToFile(logFile)
-	return pmScanner.Start()
+	if err := pmScanner.Start(); err != nil {
+		return err
+	}
+
+	for _, task := range analyzers {
+		if finisher, ok := task.(analyzer.Finisher); ok {
+			if err := finisher.Finish(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if filterSuiteAnalyzer != nil {
+		filterSuiteAnalyzer.Report().PrintWarnedSummary()
+
+		if !utils.IsEmptyString(queryPolicyReportPath) {
+			if err := filterSuiteAnalyzer.Report().WriteJSON(queryPolicyReportPath); err != nil {
+				return err
+			}
+		}
+
+		if filterSuiteAnalyzer.ShouldFail() {
+			return fmt.Errorf("%d package(s) blocked by the filter suite policy",
+				len(filterSuiteAnalyzer.Report().Blocked))
+		}
+	}
+
+	if expired := filter.ExpiredSuppressions(); len(expired) > 0 {
+		return fmt.Errorf("%d suppression(s) have expired and must be renewed or removed", len(expired))
+	}
+
+	return nil
 }